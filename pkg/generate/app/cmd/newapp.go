@@ -1,23 +1,33 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 
 	kapi "github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	kerrors "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+	kclient "github.com/GoogleCloudPlatform/kubernetes/pkg/client"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/kubectl"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/util"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/util/errors"
 	"github.com/fsouza/go-dockerclient"
 	"github.com/golang/glog"
 
+	buildapi "github.com/openshift/origin/pkg/build/api"
 	"github.com/openshift/origin/pkg/client"
 	cmdutil "github.com/openshift/origin/pkg/cmd/util"
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
 	"github.com/openshift/origin/pkg/dockerregistry"
 	"github.com/openshift/origin/pkg/generate/app"
 	"github.com/openshift/origin/pkg/generate/dockerfile"
 	"github.com/openshift/origin/pkg/generate/source"
+	imageapi "github.com/openshift/origin/pkg/image/api"
 )
 
 type AppConfig struct {
@@ -29,14 +39,67 @@ type AppConfig struct {
 	Groups       util.StringList
 	Environment  util.StringList
 
+	// AddEnvironmentToBuild also injects Environment into the BuildConfigs generated for
+	// any source or Docker builds, in addition to the runtime DeploymentConfigs. This lets
+	// callers like "oc new-build" plumb registry credentials or proxy settings into the
+	// builder pod without them leaking into the running application's environment.
+	AddEnvironmentToBuild bool
+
+	// ShortNameAliases maps an unqualified component name (e.g. "mysql") to the fully
+	// qualified image it should resolve to (e.g. "docker.io/library/mysql:5.6"),
+	// overriding the normal resolver race for that name. Populated by
+	// LoadShortNameAliases from ~/.config/openshift/shortnames.conf and/or
+	// --short-name-alias.
+	ShortNameAliases map[string]string
+	// RegistrySearch is the ordered list of registries consulted for an unqualified
+	// component name that has no entry in ShortNameAliases, e.g.
+	// []string{"docker.io", "registry.access.redhat.com"}. The first registry that has a
+	// matching image wins.
+	RegistrySearch []string
+	// ShortNameMode controls what happens when a component name is unqualified and has
+	// no entry in ShortNameAliases: "permissive" (the default) searches RegistrySearch and
+	// then falls back to the old weighted resolver race, while "enforcing" refuses the name
+	// outright, without consulting RegistrySearch, so that new-app stays deterministic and
+	// offline-friendly.
+	ShortNameMode string
+
 	TypeOfBuild string
 
+	// AssumeMatches selects the error ensureHasSource returns when the language terms
+	// detected for the source repositories leave a builder component unmatched (two or
+	// more repositories tie for its best score, or none scores above zero): when set, the
+	// error names the unmatched components. ensureHasSource never guesses a pairing,
+	// whether or not AssumeMatches is set.
+	AssumeMatches bool
+
+	// OutputFormat is the printer format Run uses to render the generated objects, e.g.
+	// "yaml" (the default), "json", "name", "go-template=...", or "jsonpath=...".
+	// OutputFormat, DryRun, and Apply are only honored by Run; RunBuilds always prints
+	// its BuildConfigs as YAML.
+	OutputFormat string
+	// DryRun runs the full pipeline and reports no error, but skips both printing and
+	// applying the generated objects - useful for validating a set of arguments.
+	DryRun bool
+	// Apply creates or updates the generated objects against the configured OpenShift and
+	// Kubernetes clients instead of printing them, so that "new-app" can be used as a
+	// complete command rather than only a generator.
+	Apply bool
+
 	localDockerResolver    app.Resolver
 	dockerRegistryResolver app.Resolver
 	imageStreamResolver    app.Resolver
 
+	osClient        client.Interface
+	kubeClient      kclient.Interface
+	originNamespace string
+
 	searcher app.Searcher
 	detector app.Detector
+
+	// repoTerms caches the language terms detectSource found for each repository, so that
+	// ensureHasSource can match multiple repositories to multiple builder components
+	// without re-running detection.
+	repoTerms map[*app.SourceRepository][]string
 }
 
 type UsageError interface {
@@ -56,6 +119,7 @@ func NewAppConfig() *AppConfig {
 			Tester:    dockerfile.NewTester(),
 		},
 		dockerRegistryResolver: app.DockerRegistryResolver{dockerregistry.NewClient()},
+		repoTerms:              map[*app.SourceRepository][]string{},
 	}
 }
 
@@ -69,6 +133,15 @@ func (c *AppConfig) SetOpenShiftClient(osclient client.Interface, originNamespac
 		Images:     osclient,
 		Namespaces: []string{originNamespace, "default"},
 	}
+	c.osClient = osclient
+	c.originNamespace = originNamespace
+}
+
+// SetKubeClient configures the Kubernetes client Apply uses to create or update Services.
+// ImageStreams, BuildConfigs, and DeploymentConfigs are all OpenShift types and go through
+// the client configured by SetOpenShiftClient instead.
+func (c *AppConfig) SetKubeClient(kubeclient kclient.Interface) {
+	c.kubeClient = kubeclient
 }
 
 // addArguments converts command line arguments into the appropriate bucket based on what they look like
@@ -109,11 +182,7 @@ func (c *AppConfig) validate() (app.ComponentReferences, []*app.SourceRepository
 		return input
 	})
 	b.AddImages(c.Components, func(input *app.ComponentInput) app.ComponentReference {
-		input.Resolver = app.PerfectMatchWeightedResolver{
-			app.WeightedResolver{Resolver: c.imageStreamResolver, Weight: 0.0},
-			app.WeightedResolver{Resolver: c.dockerRegistryResolver, Weight: 0.0},
-			app.WeightedResolver{Resolver: c.localDockerResolver, Weight: 0.0},
-		}
+		input.Resolver = c.componentResolver(input.From)
 		return input
 	})
 	b.AddGroups(c.Groups)
@@ -131,6 +200,140 @@ func (c *AppConfig) validate() (app.ComponentReferences, []*app.SourceRepository
 	return refs, repos, env, errors.NewAggregate(errs)
 }
 
+// componentResolver returns the resolver chain to use for a --image/positional component
+// reference. Qualified references (anything containing a registry or namespace, e.g.
+// "redhat/mysql:5.6") are resolved the same way as before. Unqualified short names (e.g.
+// "mysql") are resolved deterministically: first against ShortNameAliases, then by
+// searching RegistrySearch in order, instead of racing every resolver with equal weight.
+// This only builds the resolver - no registry is actually contacted until ref.Resolve()
+// runs it during resolve(), the same point in the pipeline every other resolver does its
+// work.
+func (c *AppConfig) componentResolver(name string) app.Resolver {
+	if !isShortName(name) {
+		return c.defaultResolver()
+	}
+	if alias, ok := c.ShortNameAliases[name]; ok {
+		glog.V(4).Infof("resolved short name %q to alias %q", name, alias)
+		return app.AliasResolver{Resolver: c.defaultResolver(), Value: alias}
+	}
+	if c.ShortNameMode == "enforcing" {
+		return app.ErrorResolver{Error: fmt.Errorf("%q is not a known short name and --short-name-mode=enforcing is set - add it to %s or use a fully qualified image", name, shortNameConfigPath())}
+	}
+	if len(c.RegistrySearch) == 0 {
+		return c.defaultResolver()
+	}
+	return registrySearchResolver{search: c.RegistrySearch, fallback: c.defaultResolver()}
+}
+
+// registrySearchResolver resolves an unqualified component name by checking each
+// registry in search, in order, the first time Resolve is called on it - not while
+// command line arguments are still being parsed into resolvers. If no registry in search
+// has a match, it defers to fallback.
+type registrySearchResolver struct {
+	search   []string
+	fallback app.Resolver
+}
+
+func (r registrySearchResolver) Resolve(value string) (*app.ComponentMatch, error) {
+	for _, registry := range r.search {
+		resolver := app.DockerRegistryResolver{Client: dockerregistry.NewClient(), Registry: registry}
+		if match, err := resolver.ResolveName(value); err == nil && match != nil {
+			glog.V(4).Infof("resolved short name %q to %q via registry search %q", value, match.Value, registry)
+			return match, nil
+		}
+	}
+	return r.fallback.Resolve(value)
+}
+
+// defaultResolver is the original unordered race across the image stream, Docker
+// registry, and local Docker resolvers, kept as a fallback for already-qualified names
+// and for short names that neither ShortNameAliases nor RegistrySearch can answer.
+func (c *AppConfig) defaultResolver() app.Resolver {
+	return app.PerfectMatchWeightedResolver{
+		app.WeightedResolver{Resolver: c.imageStreamResolver, Weight: 0.0},
+		app.WeightedResolver{Resolver: c.dockerRegistryResolver, Weight: 0.0},
+		app.WeightedResolver{Resolver: c.localDockerResolver, Weight: 0.0},
+	}
+}
+
+// isShortName reports whether name has no registry or namespace qualifier, e.g. "mysql"
+// is short but "redhat/mysql" and "docker.io/library/mysql:5.6" are not.
+func isShortName(name string) bool {
+	return !strings.Contains(name, "/")
+}
+
+// shortNameConfigPath returns the default location of the short name alias config file.
+func shortNameConfigPath() string {
+	home := os.Getenv("HOME")
+	return filepath.Join(home, ".config", "openshift", "shortnames.conf")
+}
+
+// LoadShortNameAliases populates ShortNameAliases and RegistrySearch from the default
+// config file location (~/.config/openshift/shortnames.conf, if present) and then
+// applies any --short-name-alias=name=image flag values on top, so that flags always win
+// over the file.
+func (c *AppConfig) LoadShortNameAliases(flagValues []string) error {
+	if c.ShortNameAliases == nil {
+		c.ShortNameAliases = map[string]string{}
+	}
+	if path := shortNameConfigPath(); len(path) > 0 {
+		if err := c.loadShortNameAliasesFile(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	for _, value := range flagValues {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			return fmt.Errorf("invalid --short-name-alias value %q, expected name=image", value)
+		}
+		c.ShortNameAliases[parts[0]] = parts[1]
+	}
+	return nil
+}
+
+// loadShortNameAliasesFile parses a simple ini-like config file with an [aliases]
+// section of "name = image" pairs and a [registries] section with a "search" key
+// holding a comma-separated registry list, mirroring the shape of
+// containers-registries.conf's short-name-aliases support.
+func (c *AppConfig) loadShortNameAliasesFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case len(line) == 0, strings.HasPrefix(line, "#"), strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			section = strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		switch section {
+		case "aliases":
+			c.ShortNameAliases[key] = value
+		case "registries":
+			if key == "search" {
+				for _, registry := range strings.Split(value, ",") {
+					if registry = strings.TrimSpace(registry); len(registry) > 0 {
+						c.RegistrySearch = append(c.RegistrySearch, registry)
+					}
+				}
+			}
+		}
+	}
+	return scanner.Err()
+}
+
 // resolve the references to ensure they are all valid, and identify any images that don't match user input.
 func (c *AppConfig) resolve(components app.ComponentReferences) error {
 	errs := []error{}
@@ -161,13 +364,24 @@ func (c *AppConfig) ensureHasSource(components app.ComponentReferences, reposito
 	if len(requiresSource) > 0 {
 		switch {
 		case len(repositories) > 1:
-			// TODO: harder problem - need to match repos up
-			if len(requiresSource) == 1 {
-				// TODO: print all suggestions
-				return fmt.Errorf("there are multiple code locations provided - use '%s~<repo>' to declare which code goes with the image", requiresSource[0])
+			matches, ambiguous, err := c.matchComponentsToRepositories(requiresSource, repositories)
+			if err != nil {
+				return err
+			}
+			if len(ambiguous) > 0 {
+				if c.AssumeMatches {
+					return fmt.Errorf("unable to confidently match a source repository to %v based on the detected language, and --assume-matches was set - use '[image]~[repo]' to declare which code goes with which image", ambiguous)
+				}
+				if len(requiresSource) == 1 {
+					return fmt.Errorf("there are multiple code locations provided - use '%s~<repo>' to declare which code goes with the image", requiresSource[0])
+				}
+				return fmt.Errorf("there are multiple code locations provided - use '[image]~[repo]' to declare which code goes with which image")
+			}
+			for component, repo := range matches {
+				glog.Infof("Matched %q to the source repository %q based on the detected language", component, repo)
+				component.Input().Use(repo)
+				repo.UsedBy(component)
 			}
-			// TODO: indicate which args don't match, and which repos don't match
-			return fmt.Errorf("there are multiple code locations provided - use '[image]~[repo]' to declare which code goes with which image")
 		case len(repositories) == 1:
 			glog.Infof("Using %q as the source for build", repositories[0])
 			for _, component := range requiresSource {
@@ -185,6 +399,107 @@ func (c *AppConfig) ensureHasSource(components app.ComponentReferences, reposito
 	return nil
 }
 
+// scoredPair is a candidate assignment between two opaque keys (a component and a
+// repository, in matchComponentsToRepositories) and how well they match. Keeping this
+// generic over interface{} lets greedyAssign's logic be unit tested without needing real
+// app.ComponentReference or app.SourceRepository values.
+type scoredPair struct {
+	a     interface{}
+	b     interface{}
+	value int
+}
+
+// greedyAssign pairs up the a-side and b-side of pairs, processing each distinct a in the
+// order it first appears in pairs and, among its still-free b candidates, claiming the
+// single best-scoring one. If an a's best score is tied between two or more free b's, that
+// a is left unassigned rather than guessed at - silently picking wrong is worse than
+// reporting ambiguity. Pairs scoring zero or less are never assigned. This is a simple
+// greedy approximation of the optimal bipartite assignment, good enough for the handful of
+// repositories/images a single "new-app" invocation deals with.
+func greedyAssign(pairs []scoredPair) map[interface{}]interface{} {
+	order := []interface{}{}
+	seen := map[interface{}]bool{}
+	for _, p := range pairs {
+		if !seen[p.a] {
+			seen[p.a] = true
+			order = append(order, p.a)
+		}
+	}
+
+	usedB := map[interface{}]bool{}
+	assigned := map[interface{}]interface{}{}
+	for _, a := range order {
+		var best *scoredPair
+		tied := false
+		for i := range pairs {
+			p := &pairs[i]
+			if p.a != a || usedB[p.b] || p.value <= 0 {
+				continue
+			}
+			switch {
+			case best == nil || p.value > best.value:
+				best, tied = p, false
+			case p.value == best.value:
+				tied = true
+			}
+		}
+		if best == nil || tied {
+			continue
+		}
+		assigned[a] = best.b
+		usedB[best.b] = true
+	}
+	return assigned
+}
+
+// matchComponentsToRepositories pairs builder components with source repositories when
+// more than one of each is present, using greedyAssign over pairs scored by how many of
+// the repository's detected language terms (see detectSource) appear in the component's
+// image name. A component is reported as ambiguous, rather than guessed at, when
+// greedyAssign leaves it unassigned: two or more repositories tie for its best score, or
+// no still-free repository scores above zero against it.
+func (c *AppConfig) matchComponentsToRepositories(components app.ComponentReferences, repositories []*app.SourceRepository) (map[app.ComponentReference]*app.SourceRepository, app.ComponentReferences, error) {
+	pairs := []scoredPair{}
+	for _, component := range components {
+		for _, repo := range repositories {
+			pairs = append(pairs, scoredPair{component, repo, c.matchScore(component, repo)})
+		}
+	}
+	assigned := greedyAssign(pairs)
+
+	matches := map[app.ComponentReference]*app.SourceRepository{}
+	ambiguous := app.ComponentReferences{}
+	for _, component := range components {
+		repo, ok := assigned[component]
+		if !ok {
+			ambiguous = append(ambiguous, component)
+			continue
+		}
+		matches[component] = repo.(*app.SourceRepository)
+	}
+	return matches, ambiguous, nil
+}
+
+// countTermMatches counts how many of terms appear as a case-insensitive substring of
+// name, e.g. name "redhat/ruby:2" scores 1 against terms ["ruby"].
+func countTermMatches(name string, terms []string) int {
+	name = strings.ToLower(name)
+	score := 0
+	for _, term := range terms {
+		if strings.Contains(name, strings.ToLower(term)) {
+			score++
+		}
+	}
+	return score
+}
+
+// matchScore counts how many of repo's detected language terms appear in component's
+// image name, e.g. a repository detected as "ruby" scores 1 against a component matching
+// "redhat/ruby:2".
+func (c *AppConfig) matchScore(component app.ComponentReference, repo *app.SourceRepository) int {
+	return countTermMatches(component.Input().String(), c.repoTerms[repo])
+}
+
 // detectSource tries to match each source repository to an image type
 func (c *AppConfig) detectSource(repositories []*app.SourceRepository) error {
 	errs := []error{}
@@ -203,21 +518,17 @@ func (c *AppConfig) detectSource(repositories []*app.SourceRepository) error {
 			errs = append(errs, err)
 			continue
 		}
+		terms := info.Terms()
+		c.repoTerms[repo] = terms
 		if info.Dockerfile != nil {
-			// TODO: this should be using the reference builder flow, possibly by moving detectSource up before other steps
-			/*if from, ok := info.Dockerfile.GetDirective("FROM"); ok {
-				input, _, err := NewComponentInput(from[0])
-				if err != nil {
-					errs = append(errs, err)
-					continue
-				}
-				input.
-			}*/
+			if err := c.resolveDockerfileBaseImage(repo, info.Dockerfile); err != nil {
+				errs = append(errs, err)
+				continue
+			}
 			repo.BuildWithDocker()
 			continue
 		}
 
-		terms := info.Terms()
 		matches, err := c.searcher.Search(terms)
 		if err != nil {
 			errs = append(errs, err)
@@ -232,8 +543,54 @@ func (c *AppConfig) detectSource(repositories []*app.SourceRepository) error {
 	return errors.NewAggregate(errs)
 }
 
-// buildPipelines converts a set of resolved, valid references into pipelines.
-func (c *AppConfig) buildPipelines(components app.ComponentReferences, environment app.Environment) (app.PipelineGroup, error) {
+// dockerfileFromImage extracts the image reference from a FROM directive's arguments,
+// discarding a multi-stage build's "AS <name>" stage alias when present. from[0] may
+// either be pre-tokenized (just the image) or the raw post-"FROM" text (e.g.
+// "mysql:5.6 AS base"); splitting on whitespace and keeping the first field handles both.
+func dockerfileFromImage(from []string) string {
+	return strings.Fields(from[0])[0]
+}
+
+// resolveDockerfileBaseImage parses the FROM directive of a detected Dockerfile and
+// resolves it through componentResolver, the same resolver chain used for --image
+// arguments, then attaches the result to repo. This lets a generated Docker-strategy
+// BuildConfig point at an ImageStreamTag (when one matches the FROM value) instead of a
+// raw Docker pull spec, and gives Dockerfile FROM images the same ShortNameAliases,
+// RegistrySearch, and --short-name-mode=enforcing handling that --image gets. Apart from a
+// short name refused by --short-name-mode=enforcing, which is returned as an error, a FROM
+// value that fails to resolve is not an error - the BuildConfig simply falls back to the
+// literal value from the Dockerfile, the same as today.
+func (c *AppConfig) resolveDockerfileBaseImage(repo *app.SourceRepository, dockerfile dockerfile.Dockerfile) error {
+	from, ok := dockerfile.GetDirective("FROM")
+	if !ok || len(from) == 0 {
+		return nil
+	}
+	image := dockerfileFromImage(from)
+	b := &app.ReferenceBuilder{}
+	b.AddImages([]string{image}, func(input *app.ComponentInput) app.ComponentReference {
+		input.Resolver = c.componentResolver(image)
+		return input
+	})
+	refs, _, errs := b.Result()
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	ref := refs[0]
+	if err := ref.Resolve(); err != nil {
+		if _, refused := ref.Input().Resolver.(app.ErrorResolver); refused {
+			return err
+		}
+		glog.V(4).Infof("Dockerfile base image %q did not resolve to an ImageStreamTag, falling back to the literal FROM value: %v", image, err)
+		return nil
+	}
+	repo.SetBaseImage(ref)
+	return nil
+}
+
+// buildPipelines converts a set of resolved, valid references into pipelines. When
+// withDeployments is false, no DeploymentConfig is attached to any pipeline - this is
+// used by RunBuilds to produce BuildConfigs only.
+func (c *AppConfig) buildPipelines(components app.ComponentReferences, environment app.Environment, withDeployments bool) (app.PipelineGroup, error) {
 	pipelines := app.PipelineGroup{}
 	for _, group := range components.Group() {
 		glog.V(2).Infof("found group: %#v", group)
@@ -254,6 +611,9 @@ func (c *AppConfig) buildPipelines(components app.ComponentReferences, environme
 				if pipeline, err = app.NewBuildPipeline(ref.Input().String(), input, strategy, source); err != nil {
 					return nil, fmt.Errorf("can't build %q: %v", ref.Input(), err)
 				}
+				if c.AddEnvironmentToBuild {
+					pipeline.Build.Env = environment
+				}
 
 			} else {
 				glog.V(2).Infof("will include %q", ref)
@@ -266,8 +626,10 @@ func (c *AppConfig) buildPipelines(components app.ComponentReferences, environme
 				}
 			}
 
-			if err := pipeline.NeedsDeployment(environment); err != nil {
-				return nil, fmt.Errorf("can't set up a deployment for %q: %v", ref.Input(), err)
+			if withDeployments {
+				if err := pipeline.NeedsDeployment(environment); err != nil {
+					return nil, fmt.Errorf("can't set up a deployment for %q: %v", ref.Input(), err)
+				}
 			}
 			common = append(common, pipeline)
 		}
@@ -299,10 +661,6 @@ func (c *AppConfig) Run(out io.Writer, helpFn func() error) error {
 		return err
 	}
 
-	if err := c.ensureHasSource(components, repositories); err != nil {
-		return err
-	}
-
 	glog.V(4).Infof("Code %v", repositories)
 	glog.V(4).Infof("Images %v", components)
 
@@ -310,7 +668,11 @@ func (c *AppConfig) Run(out io.Writer, helpFn func() error) error {
 		return err
 	}
 
-	pipelines, err := c.buildPipelines(components, app.Environment(environment))
+	if err := c.ensureHasSource(components, repositories); err != nil {
+		return err
+	}
+
+	pipelines, err := c.buildPipelines(components, app.Environment(environment), true)
 	if err != nil {
 		return err
 	}
@@ -327,7 +689,239 @@ func (c *AppConfig) Run(out io.Writer, helpFn func() error) error {
 
 	objects = app.AddServices(objects)
 
+	if c.DryRun {
+		return nil
+	}
+
+	if c.Apply {
+		return c.apply(objects, out)
+	}
+
+	format := c.OutputFormat
+	if len(format) == 0 {
+		format = "yaml"
+	}
 	list := &kapi.List{Items: objects}
+	p, _, err := kubectl.GetPrinter(format, "")
+	if err != nil {
+		return err
+	}
+	return p.PrintObj(list, out)
+}
+
+// apply creates or updates each generated object against the configured OpenShift and
+// Kubernetes clients, in dependency order - ImageStreams before BuildConfigs or
+// DeploymentConfigs that reference them, and Services last - and streams a
+// created/updated/unchanged status line per object to out.
+func (c *AppConfig) apply(objects app.Objects, out io.Writer) error {
+	if c.osClient == nil || c.kubeClient == nil {
+		return fmt.Errorf("--apply requires an OpenShift and Kubernetes client, but none were configured")
+	}
+	for _, obj := range orderObjectsForApply(objects) {
+		status, err := c.applyObject(obj)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s %q %s\n", kindOf(obj), nameOf(obj), status)
+	}
+	return nil
+}
+
+// applyObject creates obj if it doesn't already exist. If it does exist, applyObject
+// updates it only when its spec differs from what's already there, so that a repeated
+// "new-app --apply" reports "unchanged" instead of rewriting every object every time.
+func (c *AppConfig) applyObject(obj runtime.Object) (string, error) {
+	switch t := obj.(type) {
+	case *imageapi.ImageStream:
+		client := c.osClient.ImageStreams(c.originNamespace)
+		existing, err := client.Get(t.Name)
+		switch {
+		case kerrors.IsNotFound(err):
+			if _, err := client.Create(t); err != nil {
+				return "", err
+			}
+			return "created", nil
+		case err != nil:
+			return "", err
+		}
+		if reflect.DeepEqual(t.Spec, existing.Spec) {
+			return "unchanged", nil
+		}
+		t.ResourceVersion = existing.ResourceVersion
+		if _, err := client.Update(t); err != nil {
+			return "", err
+		}
+		return "updated", nil
+	case *buildapi.BuildConfig:
+		client := c.osClient.BuildConfigs(c.originNamespace)
+		existing, err := client.Get(t.Name)
+		switch {
+		case kerrors.IsNotFound(err):
+			if _, err := client.Create(t); err != nil {
+				return "", err
+			}
+			return "created", nil
+		case err != nil:
+			return "", err
+		}
+		if reflect.DeepEqual(t.Spec, existing.Spec) {
+			return "unchanged", nil
+		}
+		t.ResourceVersion = existing.ResourceVersion
+		if _, err := client.Update(t); err != nil {
+			return "", err
+		}
+		return "updated", nil
+	case *deployapi.DeploymentConfig:
+		client := c.osClient.DeploymentConfigs(c.originNamespace)
+		existing, err := client.Get(t.Name)
+		switch {
+		case kerrors.IsNotFound(err):
+			if _, err := client.Create(t); err != nil {
+				return "", err
+			}
+			return "created", nil
+		case err != nil:
+			return "", err
+		}
+		if reflect.DeepEqual(t.Template, existing.Template) {
+			return "unchanged", nil
+		}
+		t.ResourceVersion = existing.ResourceVersion
+		if _, err := client.Update(t); err != nil {
+			return "", err
+		}
+		return "updated", nil
+	case *kapi.Service:
+		client := c.kubeClient.Services(c.originNamespace)
+		existing, err := client.Get(t.Name)
+		switch {
+		case kerrors.IsNotFound(err):
+			if _, err := client.Create(t); err != nil {
+				return "", err
+			}
+			return "created", nil
+		case err != nil:
+			return "", err
+		}
+		if reflect.DeepEqual(t.Spec, existing.Spec) {
+			return "unchanged", nil
+		}
+		t.ResourceVersion = existing.ResourceVersion
+		if _, err := client.Update(t); err != nil {
+			return "", err
+		}
+		return "updated", nil
+	default:
+		return "", fmt.Errorf("unrecognized object type %T, cannot apply it", obj)
+	}
+}
+
+// orderObjectsForApply sorts objects so that ImageStreams are applied before
+// BuildConfigs and DeploymentConfigs that may reference them, and Services are applied
+// last since they only depend on labels the other objects already carry.
+func orderObjectsForApply(objects app.Objects) app.Objects {
+	var streams, builds, deploys, services, other app.Objects
+	for _, obj := range objects {
+		switch obj.(type) {
+		case *imageapi.ImageStream:
+			streams = append(streams, obj)
+		case *buildapi.BuildConfig:
+			builds = append(builds, obj)
+		case *deployapi.DeploymentConfig:
+			deploys = append(deploys, obj)
+		case *kapi.Service:
+			services = append(services, obj)
+		default:
+			other = append(other, obj)
+		}
+	}
+	ordered := app.Objects{}
+	ordered = append(ordered, streams...)
+	ordered = append(ordered, builds...)
+	ordered = append(ordered, deploys...)
+	ordered = append(ordered, services...)
+	ordered = append(ordered, other...)
+	return ordered
+}
+
+// kindOf returns a short, lower-case description of obj's kind for status output.
+func kindOf(obj runtime.Object) string {
+	switch obj.(type) {
+	case *imageapi.ImageStream:
+		return "imagestream"
+	case *buildapi.BuildConfig:
+		return "buildconfig"
+	case *deployapi.DeploymentConfig:
+		return "deploymentconfig"
+	case *kapi.Service:
+		return "service"
+	default:
+		return "object"
+	}
+}
+
+// nameOf returns obj's name, or the empty string if obj is not one of the kinds new-app
+// generates.
+func nameOf(obj runtime.Object) string {
+	switch t := obj.(type) {
+	case *imageapi.ImageStream:
+		return t.Name
+	case *buildapi.BuildConfig:
+		return t.Name
+	case *deployapi.DeploymentConfig:
+		return t.Name
+	case *kapi.Service:
+		return t.Name
+	default:
+		return ""
+	}
+}
+
+// RunBuilds executes the provided config and generates only the BuildConfigs implied by
+// it, skipping NeedsDeployment and AddServices. This backs commands like "oc new-build"
+// that want to create builds without also standing up a deployment.
+func (c *AppConfig) RunBuilds(out io.Writer) error {
+	components, repositories, environment, err := c.validate()
+	if err != nil {
+		return err
+	}
+
+	if err := c.resolve(components); err != nil {
+		return err
+	}
+
+	if err := c.detectSource(repositories); err != nil {
+		return err
+	}
+
+	if err := c.ensureHasSource(components, repositories); err != nil {
+		return err
+	}
+
+	pipelines, err := c.buildPipelines(components, app.Environment(environment), false)
+	if err != nil {
+		return err
+	}
+
+	objects := app.Objects{}
+	accept := app.NewAcceptFirst()
+	for _, p := range pipelines {
+		obj, err := p.Objects(accept)
+		if err != nil {
+			return fmt.Errorf("can't setup %q: %v", p.From, err)
+		}
+		objects = append(objects, obj...)
+	}
+
+	buildConfigs := app.Objects{}
+	for _, obj := range objects {
+		if _, ok := obj.(*buildapi.BuildConfig); ok {
+			buildConfigs = append(buildConfigs, obj)
+		}
+	}
+
+	list := &kapi.List{Items: buildConfigs}
 	p, _, err := kubectl.GetPrinter("yaml", "")
 	if err != nil {
 		return err
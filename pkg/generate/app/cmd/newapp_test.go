@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	kapi "github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	ktestclient "github.com/GoogleCloudPlatform/kubernetes/pkg/client/testclient"
+
+	buildapi "github.com/openshift/origin/pkg/build/api"
+	"github.com/openshift/origin/pkg/client/testclient"
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	"github.com/openshift/origin/pkg/generate/app"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+func TestLoadShortNameAliasesFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shortnames")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "shortnames.conf")
+	contents := `
+# a leading comment, and a blank line above should be ignored
+[aliases]
+mysql = docker.io/library/mysql:5.6
+; a semicolon comment is also ignored
+ruby  =  redhat/ruby:2
+
+[registries]
+search = docker.io, registry.access.redhat.com , quay.io
+`
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c := &AppConfig{ShortNameAliases: map[string]string{}}
+	if err := c.loadShortNameAliasesFile(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedAliases := map[string]string{
+		"mysql": "docker.io/library/mysql:5.6",
+		"ruby":  "redhat/ruby:2",
+	}
+	if !reflect.DeepEqual(c.ShortNameAliases, expectedAliases) {
+		t.Errorf("expected aliases %v, got %v", expectedAliases, c.ShortNameAliases)
+	}
+
+	expectedSearch := []string{"docker.io", "registry.access.redhat.com", "quay.io"}
+	if !reflect.DeepEqual(c.RegistrySearch, expectedSearch) {
+		t.Errorf("expected registry search %v, got %v", expectedSearch, c.RegistrySearch)
+	}
+}
+
+func TestLoadShortNameAliasesFileMissing(t *testing.T) {
+	c := &AppConfig{ShortNameAliases: map[string]string{}}
+	err := c.loadShortNameAliasesFile(filepath.Join(os.TempDir(), "does-not-exist-shortnames.conf"))
+	if err == nil || !os.IsNotExist(err) {
+		t.Errorf("expected a not-exist error, got %v", err)
+	}
+}
+
+func TestLoadShortNameAliasesFlagsOverrideFile(t *testing.T) {
+	home, err := ioutil.TempDir("", "shortnames-home")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(home)
+
+	configDir := filepath.Join(home, ".config", "openshift")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	contents := "[aliases]\nmysql = docker.io/library/mysql:5.6\n"
+	if err := ioutil.WriteFile(filepath.Join(configDir, "shortnames.conf"), []byte(contents), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", oldHome)
+
+	c := &AppConfig{}
+	if err := c.LoadShortNameAliases([]string{"mysql=quay.io/example/mysql:8"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if c.ShortNameAliases["mysql"] != "quay.io/example/mysql:8" {
+		t.Errorf("expected the flag-set alias to win over the config file, got %q", c.ShortNameAliases["mysql"])
+	}
+}
+
+func TestLoadShortNameAliasesInvalidFlag(t *testing.T) {
+	c := &AppConfig{}
+	if err := c.LoadShortNameAliases([]string{"not-a-valid-alias"}); err == nil {
+		t.Errorf("expected an error for a flag value without an '=', got none")
+	}
+}
+
+func TestCountTermMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		terms []string
+		want  int
+	}{
+		{"redhat/ruby:2", []string{"ruby"}, 1},
+		{"redhat/ruby-php:1", []string{"ruby"}, 1},
+		{"redhat/ruby-php:1", []string{"ruby", "php"}, 2},
+		{"redhat/ruby-php:1", []string{"PHP"}, 1},
+		{"redhat/nodejs:8", []string{"ruby"}, 0},
+	}
+	for _, test := range tests {
+		if got := countTermMatches(test.name, test.terms); got != test.want {
+			t.Errorf("countTermMatches(%q, %v) = %d, want %d", test.name, test.terms, got, test.want)
+		}
+	}
+}
+
+// TestGreedyAssignLeavesTiedComponentAmbiguous reproduces a genuine tie: builder B1 scores
+// 2 against both R1 and R2, while B2 scores 1 against R3 only (and 0 against R1/R2).
+// Because B1 has no single best repository, it must be left unassigned rather than
+// arbitrarily claiming R1 or R2 - silently guessing would defeat the entire point of
+// --assume-matches, whose job is to turn exactly this case into a hard error. B2, which
+// has no competing tie, should still be assigned to R3.
+func TestGreedyAssignLeavesTiedComponentAmbiguous(t *testing.T) {
+	b1, b2 := "B1", "B2"
+	r1, r2, r3 := "R1", "R2", "R3"
+	pairs := []scoredPair{
+		{b1, r1, 2},
+		{b1, r2, 2},
+		{b1, r3, 0},
+		{b2, r1, 0},
+		{b2, r2, 0},
+		{b2, r3, 1},
+	}
+
+	assigned := greedyAssign(pairs)
+
+	if _, ok := assigned[b1]; ok {
+		t.Errorf("expected %v to be left unassigned due to a tie, got %v", b1, assigned[b1])
+	}
+	if assigned[b2] != r3 {
+		t.Errorf("expected %v to be assigned to %v, got %v", b2, r3, assigned[b2])
+	}
+}
+
+// TestGreedyAssignDoesNotResolveStructuralAmbiguity covers a tie that a full bipartite
+// assignment could settle but greedyAssign does not: C1 (image "redhat/ruby-php:1") scores
+// 1 against both R1 (detected "php") and R2 (detected "ruby"), while C2 (image
+// "redhat/ruby:2") scores 1 against R2 only. C1 is processed first, while both R1 and R2
+// are still free, so its tie is left ambiguous and C2 then claims its only candidate, R2.
+func TestGreedyAssignDoesNotResolveStructuralAmbiguity(t *testing.T) {
+	c1, c2 := "C1", "C2"
+	r1, r2 := "R1", "R2"
+	pairs := []scoredPair{
+		{c1, r1, 1},
+		{c1, r2, 1},
+		{c2, r2, 1},
+		{c2, r1, 0},
+	}
+
+	assigned := greedyAssign(pairs)
+
+	if _, ok := assigned[c1]; ok {
+		t.Errorf("expected %v to be left unassigned due to a tie, got %v", c1, assigned[c1])
+	}
+	if assigned[c2] != r2 {
+		t.Errorf("expected %v to be assigned to %v, got %v", c2, r2, assigned[c2])
+	}
+}
+
+func TestGreedyAssignSkipsZeroScores(t *testing.T) {
+	c1, r1 := "C1", "R1"
+	assigned := greedyAssign([]scoredPair{{c1, r1, 0}})
+	if len(assigned) != 0 {
+		t.Errorf("expected no assignment for a zero-scoring pair, got %v", assigned)
+	}
+}
+
+func TestDockerfileFromImage(t *testing.T) {
+	tests := []struct {
+		from []string
+		want string
+	}{
+		{[]string{"mysql:5.6"}, "mysql:5.6"},
+		{[]string{"mysql:5.6 AS base"}, "mysql:5.6"},
+		{[]string{"base"}, "base"},
+	}
+	for _, test := range tests {
+		if got := dockerfileFromImage(test.from); got != test.want {
+			t.Errorf("dockerfileFromImage(%v) = %q, want %q", test.from, got, test.want)
+		}
+	}
+}
+
+func TestOrderObjectsForApply(t *testing.T) {
+	service := &kapi.Service{ObjectMeta: kapi.ObjectMeta{Name: "svc"}}
+	build := &buildapi.BuildConfig{ObjectMeta: kapi.ObjectMeta{Name: "build"}}
+	deploy := &deployapi.DeploymentConfig{ObjectMeta: kapi.ObjectMeta{Name: "deploy"}}
+	stream := &imageapi.ImageStream{ObjectMeta: kapi.ObjectMeta{Name: "stream"}}
+
+	ordered := orderObjectsForApply(app.Objects{service, build, deploy, stream})
+
+	want := app.Objects{stream, build, deploy, service}
+	if !reflect.DeepEqual(ordered, want) {
+		t.Errorf("expected streams, then builds, then deploys, then services, got %v", ordered)
+	}
+}
+
+func TestApplyRequiresClients(t *testing.T) {
+	c := &AppConfig{}
+	err := c.apply(app.Objects{}, &bytes.Buffer{})
+	if err == nil {
+		t.Errorf("expected an error when no OpenShift/Kubernetes clients are configured")
+	}
+}
+
+func TestApplyObjectCreatesImageStream(t *testing.T) {
+	fake := testclient.NewSimpleFake()
+	c := &AppConfig{osClient: fake, kubeClient: ktestclient.NewSimpleFake(), originNamespace: "ns"}
+
+	stream := &imageapi.ImageStream{
+		ObjectMeta: kapi.ObjectMeta{Name: "stream", Namespace: "ns"},
+		Spec:       imageapi.ImageStreamSpec{DockerImageRepository: "mysql"},
+	}
+	status, err := c.applyObject(stream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status != "created" {
+		t.Errorf("expected status %q, got %q", "created", status)
+	}
+}
+
+func TestApplyObjectReportsUnchanged(t *testing.T) {
+	existing := &imageapi.ImageStream{
+		ObjectMeta: kapi.ObjectMeta{Name: "stream", Namespace: "ns"},
+		Spec:       imageapi.ImageStreamSpec{DockerImageRepository: "mysql"},
+	}
+	fake := testclient.NewSimpleFake(existing)
+	c := &AppConfig{osClient: fake, kubeClient: ktestclient.NewSimpleFake(), originNamespace: "ns"}
+
+	same := &imageapi.ImageStream{
+		ObjectMeta: kapi.ObjectMeta{Name: "stream", Namespace: "ns"},
+		Spec:       imageapi.ImageStreamSpec{DockerImageRepository: "mysql"},
+	}
+	status, err := c.applyObject(same)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status != "unchanged" {
+		t.Errorf("expected status %q, got %q", "unchanged", status)
+	}
+}
+
+func TestApplyObjectUpdatesWhenSpecDiffers(t *testing.T) {
+	existing := &imageapi.ImageStream{
+		ObjectMeta: kapi.ObjectMeta{Name: "stream", Namespace: "ns"},
+		Spec:       imageapi.ImageStreamSpec{DockerImageRepository: "mysql"},
+	}
+	fake := testclient.NewSimpleFake(existing)
+	c := &AppConfig{osClient: fake, kubeClient: ktestclient.NewSimpleFake(), originNamespace: "ns"}
+
+	changed := &imageapi.ImageStream{
+		ObjectMeta: kapi.ObjectMeta{Name: "stream", Namespace: "ns"},
+		Spec:       imageapi.ImageStreamSpec{DockerImageRepository: "postgres"},
+	}
+	status, err := c.applyObject(changed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status != "updated" {
+		t.Errorf("expected status %q, got %q", "updated", status)
+	}
+}